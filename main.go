@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"google.golang.org/adk/agent"
@@ -12,16 +15,203 @@ import (
 	"google.golang.org/adk/cmd/launcher/full"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/loadartifactstool"
+	"google.golang.org/genai"
 
 	"example.com/adk-agent/pkg/config"
+	"example.com/adk-agent/pkg/history"
 	"example.com/adk-agent/pkg/openaiadapter"
 	"example.com/adk-agent/pkg/services"
 	"example.com/adk-agent/pkg/tools"
 )
 
+// defaultInstruction is used when the active profile doesn't set its own
+// SystemPrompt.
+const defaultInstruction = `You are a helpful AI assistant.
+You have access to a search tool, a fetch tool, and a local file artifact tool.
+If the user asks for information you don't know or real-time facts, YOU MUST use the 'search' tool, then 'fetch' a promising result to read it in full.
+If the user asks about files or artifacts, use the 'load_artifacts' tool to read them.`
+
+// filterTools keeps only the tools named in allowlist, preserving order.
+func filterTools(tools []tool.Tool, allowlist []string) []tool.Tool {
+	allowed := make(map[string]bool, len(allowlist))
+	for _, name := range allowlist {
+		allowed[name] = true
+	}
+
+	filtered := make([]tool.Tool, 0, len(tools))
+	for _, t := range tools {
+		if allowed[t.Name()] {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// runConvCommand implements "adk-agent conv list|show <id>|rm <id>|new|
+// resume <id>|branch <id>@<msgIdx>" against the on-disk conversation store.
+// To actually continue a conversation in a live console run, use
+// "adk-agent console --resume <id>" (see history.RecordingModel) instead of
+// "conv resume", which only loads the stored log and prints its length.
+//
+// NOTE: launcher.Config has no hook for registering extra HTTP routes, so
+// web mode doesn't expose conv list/show/rm/branch over HTTP the way the
+// CLI does, and --resume is rejected outside console mode (see
+// isConsoleMode); the operations above are CLI-only.
+func runConvCommand(args []string) error {
+	store, err := history.NewStore("")
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: adk-agent conv <list|show|rm|new|resume|branch> [args]")
+	}
+
+	switch args[0] {
+	case "list":
+		ids, err := store.List()
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			fmt.Println(id)
+		}
+	case "new":
+		id, err := store.New()
+		if err != nil {
+			return err
+		}
+		fmt.Println(id)
+	case "show":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: adk-agent conv show <id>")
+		}
+		contents, err := store.Load(args[1])
+		if err != nil {
+			return err
+		}
+		for i, c := range contents {
+			fmt.Printf("[%d] %s: %s\n", i, c.Role, summarizeContent(c))
+		}
+	case "rm":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: adk-agent conv rm <id>")
+		}
+		return store.Remove(args[1])
+	case "resume":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: adk-agent conv resume <id>")
+		}
+		contents, err := store.Resume(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Resuming conversation %s (%d messages)\n", args[1], len(contents))
+	case "branch":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: adk-agent conv branch <id>@<msgIdx>")
+		}
+		target, idxStr, ok := strings.Cut(args[1], "@")
+		if !ok {
+			return fmt.Errorf("usage: adk-agent conv branch <id>@<msgIdx>")
+		}
+		idx, err := strconv.Atoi(idxStr)
+		if err != nil {
+			return fmt.Errorf("invalid message index %q: %w", idxStr, err)
+		}
+		newID, err := store.Branch(target, idx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(newID)
+	default:
+		return fmt.Errorf("unknown conv subcommand: %s", args[0])
+	}
+	return nil
+}
+
+// summarizeContent renders a *genai.Content as a single line for "conv show".
+func summarizeContent(c *genai.Content) string {
+	var b strings.Builder
+	for _, p := range c.Parts {
+		switch {
+		case p.Text != "":
+			b.WriteString(p.Text)
+		case p.FunctionCall != nil:
+			fmt.Fprintf(&b, "<call %s>", p.FunctionCall.Name)
+		case p.FunctionResponse != nil:
+			fmt.Fprintf(&b, "<response %s>", p.FunctionResponse.Name)
+		}
+	}
+	return b.String()
+}
+
+// stripProfileFlag extracts "--profile <name>" / "--profile=<name>" from
+// args, returning the remaining args and the selected profile name. Falls
+// back to ADK_PROFILE if no flag is present.
+func stripProfileFlag(args []string) ([]string, string) {
+	profile := os.Getenv("ADK_PROFILE")
+	remaining := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--profile" && i+1 < len(args) {
+			profile = args[i+1]
+			i++
+			continue
+		}
+		if name, ok := strings.CutPrefix(arg, "--profile="); ok {
+			profile = name
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return remaining, profile
+}
+
+// stripResumeFlag extracts "--resume <id>" / "--resume=<id>" from args, so
+// "adk-agent console --resume <id>" continues a stored conversation instead
+// of starting blank. Falls back to ADK_RESUME if no flag is present.
+func stripResumeFlag(args []string) ([]string, string) {
+	resume := os.Getenv("ADK_RESUME")
+	remaining := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--resume" && i+1 < len(args) {
+			resume = args[i+1]
+			i++
+			continue
+		}
+		if id, ok := strings.CutPrefix(arg, "--resume="); ok {
+			resume = id
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return remaining, resume
+}
+
+// isConsoleMode reports whether runArgs selects the single-user console
+// interface rather than the web/api/webui launcher, which serves concurrent
+// browser sessions. --resume is only meaningful in console mode: splicing a
+// resumed conversation's history into "the next new conversation" a
+// RecordingModel sees only makes sense when there's exactly one conversation
+// to resume.
+func isConsoleMode(runArgs []string) bool {
+	return len(runArgs) == 1 && runArgs[0] == "console"
+}
+
 func main() {
 	// Load .env file (optional)
 	_ = godotenv.Load()
+
+	if len(os.Args) > 1 && os.Args[1] == "conv" {
+		if err := runConvCommand(os.Args[2:]); err != nil {
+			log.Fatalf("conv command failed: %v", err)
+		}
+		return
+	}
+
 	ctx := context.Background()
 
 	wizard := config.NewInteractiveWizard()
@@ -31,7 +221,8 @@ func main() {
 
 	// Step 1: Determine Interface Mode (Run Mode)
 	// Independent of Agent Configuration
-	args := os.Args[1:]
+	args, profile := stripProfileFlag(os.Args[1:])
+	args, resumeID := stripResumeFlag(args)
 	if len(args) == 0 {
 		// No args -> Ask User
 		runArgs, err = wizard.SelectInterfaceMode()
@@ -61,6 +252,12 @@ func main() {
 		if err != nil {
 			log.Fatalf("Configuration failed: %v", err)
 		}
+	} else if profile != "" {
+		// Non-Interactive (Shortcut): Load a named profile from the YAML gallery.
+		llmConfig, err = config.NewYAMLLoader("", profile).LoadLLMConfig()
+		if err != nil {
+			log.Fatalf("Failed to load profile %q: %v", profile, err)
+		}
 	} else {
 		// Non-Interactive (Shortcut): Use defaults or Env Vars
 		llmConfig = &config.LLMConfig{
@@ -77,11 +274,16 @@ func main() {
 	}
 
 	// Step 3: Initialize Components
-	searchTool, err := tools.NewSearchTool()
+	searchTool, err := tools.NewSearchTool(nil)
 	if err != nil {
 		log.Fatalf("Failed to create search tool: %v", err)
 	}
 
+	fetchTool, err := tools.NewFetchTool()
+	if err != nil {
+		log.Fatalf("Failed to create fetch tool: %v", err)
+	}
+
 	// Create FileSystem Artifact Service
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -92,20 +294,55 @@ func main() {
 	// Create Load Artifacts Tool
 	loadArtifactsTool := loadartifactstool.New()
 
-	model := openaiadapter.NewModel(llmConfig.BaseURL, llmConfig.ModelName, llmConfig.APIKey)
+	// Persist every conversation the launcher carries so console/web sessions
+	// survive process exit. RecordingModel assigns each conversation its own
+	// id lazily, since a single process may serve many concurrent web
+	// sessions and a launch-time id would mix them together.
+	historyStore, err := history.NewStore("")
+	if err != nil {
+		log.Fatalf("Failed to open conversation history: %v", err)
+	}
+
+	model := history.NewRecordingModel(
+		openaiadapter.NewModel(llmConfig.BaseURL, llmConfig.ModelName, llmConfig.APIKey, llmConfig.Temperature, llmConfig.MaxTokens),
+		historyStore,
+	)
+
+	if resumeID != "" {
+		if !isConsoleMode(runArgs) {
+			log.Fatalf("--resume is only supported in console mode: a web session serves many concurrent visitors, and there's no single conversation to splice a resumed history into")
+		}
+		seed, err := historyStore.Resume(resumeID)
+		if err != nil {
+			log.Fatalf("Failed to resume conversation %q: %v", resumeID, err)
+		}
+		model.Resume(resumeID, seed)
+		fmt.Printf("Resuming conversation %s\n", resumeID)
+	}
+
+	instruction := defaultInstruction
+	if llmConfig.SystemPrompt != "" {
+		instruction = llmConfig.SystemPrompt
+		if llmConfig.Template != nil {
+			instruction = llmConfig.Template.Prefix + instruction + llmConfig.Template.Suffix
+		}
+	}
+
+	agentTools := []tool.Tool{
+		searchTool,
+		fetchTool,
+		loadArtifactsTool,
+	}
+	if len(llmConfig.ToolAllowlist) > 0 {
+		agentTools = filterTools(agentTools, llmConfig.ToolAllowlist)
+	}
 
 	searchAgent, err := llmagent.New(llmagent.Config{
 		Name:        "search_agent",
 		Model:       model,
 		Description: "A helpful assistant.",
-		Instruction: `You are a helpful AI assistant.
-You have access to a search tool and a local file artifact tool.
-If the user asks for information you don't know or real-time facts, YOU MUST use the 'search' tool.
-If the user asks about files or artifacts, use the 'load_artifacts' tool to read them.`,
-		Tools: []tool.Tool{
-			searchTool,
-			loadArtifactsTool,
-		},
+		Instruction: instruction,
+		Tools:       agentTools,
 	})
 	if err != nil {
 		log.Fatalf("Failed to create agent: %v", err)
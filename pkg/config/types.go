@@ -6,6 +6,19 @@ type LLMConfig struct {
 	BaseURL   string
 	APIKey    string
 	ModelName string
+
+	// SystemPrompt, if set, overrides the agent's default instruction.
+	SystemPrompt string
+	// Temperature and MaxTokens, if set, are passed through to the chat
+	// completion request. Pointers distinguish "unset" from the zero value.
+	Temperature *float32
+	MaxTokens   *int
+	// ToolAllowlist, if non-empty, restricts the agent to tools named here.
+	ToolAllowlist []string
+	// Template, if set, wraps SystemPrompt with a custom prefix/suffix
+	// before it is used as the agent's instruction. It has no effect when
+	// SystemPrompt is empty.
+	Template *Template
 }
 
 // Loader defines how configuration is loaded.
@@ -4,11 +4,16 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 )
 
 // InteractiveWizard implements Loader using CLI prompts.
-type InteractiveWizard struct{}
+type InteractiveWizard struct {
+	// ModelsDir is where LoadLLMConfig looks for gallery profiles (see
+	// YAMLLoader). Empty defaults to $ADK_MODELS_PATH, then "./models".
+	ModelsDir string
+}
 
 // NewInteractiveWizard creates a new wizard instance.
 func NewInteractiveWizard() *InteractiveWizard {
@@ -33,29 +38,45 @@ func (w *InteractiveWizard) SelectInterfaceMode() ([]string, error) {
 	return []string{"console"}, nil
 }
 
-// LoadLLMConfig prompts the user to configure the LLM Provider.
+// LoadLLMConfig prompts the user to configure the LLM Provider. If profiles
+// are discoverable via a YAMLLoader (see ModelsDir), they are offered as
+// additional numbered choices alongside the built-in Ollama/Corporate ones.
 func (w *InteractiveWizard) LoadLLMConfig() (*LLMConfig, error) {
 	reader := bufio.NewReader(os.Stdin)
 	config := &LLMConfig{}
 
+	profiles, err := NewYAMLLoader(w.ModelsDir, "").Discover()
+	if err != nil {
+		// Gallery is optional; fall back to the built-in choices silently.
+		profiles = nil
+	}
+
 	fmt.Println("\n=== Agent Configuration ===")
-	// Ask for Provider (Local/Corporate)
-	w.askProviderDetails(reader, config)
+	// Ask for Provider (Local/Corporate/Gallery)
+	w.askProviderDetails(reader, config, profiles)
 
 	fmt.Println("\nConfiguration Complete.")
 	fmt.Println("------------------------")
 	return config, nil
 }
 
-func (w *InteractiveWizard) askProviderDetails(reader *bufio.Reader, config *LLMConfig) {
+func (w *InteractiveWizard) askProviderDetails(reader *bufio.Reader, config *LLMConfig, profiles []*Profile) {
 	fmt.Println("Select LLM Provider:")
 	fmt.Println("1. Local Ollama (Default)")
 	fmt.Println("2. Corporate / Private LLM (OpenAI Compatible)")
+	for i, p := range profiles {
+		fmt.Printf("%d. %s (from %s)\n", i+3, p.Name, w.ModelsDir)
+	}
 	fmt.Print("Enter choice [1]: ")
 
 	choice, _ := reader.ReadString('\n')
 	choice = strings.TrimSpace(choice)
 
+	if idx, err := strconv.Atoi(choice); err == nil && idx >= 3 && idx-3 < len(profiles) {
+		*config = *profiles[idx-3].LLMConfig()
+		return
+	}
+
 	if choice == "2" {
 		// Corporate LLM
 		fmt.Print("Enter Base URL (e.g. http://llm.corp.net/v1): ")
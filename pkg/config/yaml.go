@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultModelsDir is used when neither a directory is passed to
+// NewYAMLLoader nor ADK_MODELS_PATH is set.
+const defaultModelsDir = "./models"
+
+// envVarPattern matches ${ENV_VAR} references inside YAML string values.
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Template describes how to wrap a prompt for providers that expect a
+// custom instruction format instead of the default chat message layout.
+type Template struct {
+	Prefix string `yaml:"prefix"`
+	Suffix string `yaml:"suffix"`
+}
+
+// Profile is one named provider entry loaded from a models/*.yaml file.
+type Profile struct {
+	Name          string    `yaml:"name"`
+	BaseURL       string    `yaml:"base_url"`
+	APIKey        string    `yaml:"api_key"`
+	ModelName     string    `yaml:"model"`
+	SystemPrompt  string    `yaml:"system_prompt"`
+	Temperature   *float32  `yaml:"temperature"`
+	MaxTokens     *int      `yaml:"max_tokens"`
+	ToolAllowlist []string  `yaml:"tools"`
+	Template      *Template `yaml:"template"`
+}
+
+// LLMConfig converts a Profile into the LLMConfig the rest of the app uses.
+func (p *Profile) LLMConfig() *LLMConfig {
+	return &LLMConfig{
+		BaseURL:       p.BaseURL,
+		APIKey:        p.APIKey,
+		ModelName:     p.ModelName,
+		SystemPrompt:  p.SystemPrompt,
+		Temperature:   p.Temperature,
+		MaxTokens:     p.MaxTokens,
+		ToolAllowlist: p.ToolAllowlist,
+		Template:      p.Template,
+	}
+}
+
+// YAMLLoader implements Loader by reading named provider profiles from
+// *.yaml files in a directory, e.g. ./models/corporate.yaml.
+type YAMLLoader struct {
+	// Dir is the directory to scan for profile files. Defaults to
+	// ADK_MODELS_PATH, falling back to "./models".
+	Dir string
+	// Profile, if set, selects a specific profile by name. If empty,
+	// LoadLLMConfig uses the first profile found (sorted by file name).
+	Profile string
+}
+
+// NewYAMLLoader creates a loader rooted at dir. An empty dir resolves to
+// $ADK_MODELS_PATH, or "./models" if that is also unset.
+func NewYAMLLoader(dir, profile string) *YAMLLoader {
+	if dir == "" {
+		dir = os.Getenv("ADK_MODELS_PATH")
+	}
+	if dir == "" {
+		dir = defaultModelsDir
+	}
+	return &YAMLLoader{Dir: dir, Profile: profile}
+}
+
+// LoadLLMConfig implements Loader by resolving l.Profile (or the first
+// discovered profile) to an *LLMConfig.
+func (l *YAMLLoader) LoadLLMConfig() (*LLMConfig, error) {
+	profiles, err := l.Discover()
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles found in %s", l.Dir)
+	}
+
+	if l.Profile == "" {
+		return profiles[0].LLMConfig(), nil
+	}
+	for _, p := range profiles {
+		if p.Name == l.Profile {
+			return p.LLMConfig(), nil
+		}
+	}
+	return nil, fmt.Errorf("profile %q not found in %s", l.Profile, l.Dir)
+}
+
+// Discover reads every *.yaml file in l.Dir and returns the parsed
+// profiles, sorted by file name for deterministic ordering.
+func (l *YAMLLoader) Discover() ([]*Profile, error) {
+	matches, err := filepath.Glob(filepath.Join(l.Dir, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob %s: %w", l.Dir, err)
+	}
+	sort.Strings(matches)
+
+	var profiles []*Profile
+	for _, path := range matches {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		var p Profile
+		if err := yaml.Unmarshal(expandEnv(raw), &p); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if p.Name == "" {
+			p.Name = trimExt(filepath.Base(path))
+		}
+		profiles = append(profiles, &p)
+	}
+	return profiles, nil
+}
+
+// expandEnv replaces ${ENV_VAR} references with their environment value,
+// leaving the reference untouched if the variable is unset.
+func expandEnv(raw []byte) []byte {
+	return envVarPattern.ReplaceAllFunc(raw, func(match []byte) []byte {
+		name := envVarPattern.FindSubmatch(match)[1]
+		if val, ok := os.LookupEnv(string(name)); ok {
+			return []byte(val)
+		}
+		return match
+	})
+}
+
+func trimExt(name string) string {
+	ext := filepath.Ext(name)
+	return name[:len(name)-len(ext)]
+}
@@ -0,0 +1,211 @@
+// Package history persists multi-turn conversations to disk so console mode
+// doesn't lose context when the process exits. Each conversation is stored
+// as one *genai.Content per line (JSON Lines), so FunctionCall/
+// FunctionResponse parts round-trip exactly and a resumed session can
+// continue a tool-using conversation where it left off.
+package history
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// Store manages conversation logs under a directory, one file per
+// conversation: <Dir>/<id>.jsonl.
+type Store struct {
+	Dir string
+}
+
+// DefaultDir returns ~/.adk-agent/conversations.
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+	return filepath.Join(home, ".adk-agent", "conversations"), nil
+}
+
+// NewStore creates a Store rooted at dir, creating it if needed. An empty
+// dir resolves to DefaultDir().
+func NewStore(dir string) (*Store, error) {
+	if dir == "" {
+		var err error
+		dir, err = DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create conversations directory %s: %w", dir, err)
+	}
+	return &Store{Dir: dir}, nil
+}
+
+// validateID guards against path traversal out of s.Dir via a conversation
+// ID taken straight from CLI args (see fs_artifact_service.go's
+// validateFileName for the same guard applied to artifact names).
+func validateID(id string) error {
+	if id == "" || strings.Contains(id, "..") || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid conversation id: %s", id)
+	}
+	return nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.Dir, id+".jsonl")
+}
+
+// New creates an empty conversation and returns its ID.
+func (s *Store) New() (string, error) {
+	id := newID()
+	if err := s.save(id, nil); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// List returns known conversation IDs, sorted (oldest first, since IDs are
+// timestamp-prefixed).
+func (s *Store) List() ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.Dir, err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(entry.Name(), ".jsonl"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Load reads every *genai.Content in the conversation id, in order.
+func (s *Store) Load(id string) ([]*genai.Content, error) {
+	if err := validateID(id); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(s.path(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open conversation %s: %w", id, err)
+	}
+	defer f.Close()
+
+	var contents []*genai.Content
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var c genai.Content
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			return nil, fmt.Errorf("failed to parse conversation %s: %w", id, err)
+		}
+		contents = append(contents, &c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read conversation %s: %w", id, err)
+	}
+	return contents, nil
+}
+
+// Append writes one more turn onto the end of conversation id.
+func (s *Store) Append(id string, content *genai.Content) error {
+	if err := validateID(id); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("failed to marshal content: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path(id), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open conversation %s: %w", id, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(raw, '\n')); err != nil {
+		return fmt.Errorf("failed to append to conversation %s: %w", id, err)
+	}
+	return nil
+}
+
+// save overwrites conversation id with contents, one per line.
+func (s *Store) save(id string, contents []*genai.Content) error {
+	f, err := os.Create(s.path(id))
+	if err != nil {
+		return fmt.Errorf("failed to create conversation %s: %w", id, err)
+	}
+	defer f.Close()
+
+	for _, c := range contents {
+		raw, err := json.Marshal(c)
+		if err != nil {
+			return fmt.Errorf("failed to marshal content: %w", err)
+		}
+		if _, err := f.Write(append(raw, '\n')); err != nil {
+			return fmt.Errorf("failed to write conversation %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Remove deletes a conversation's log.
+func (s *Store) Remove(id string) error {
+	if err := validateID(id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(id)); err != nil {
+		return fmt.Errorf("failed to remove conversation %s: %w", id, err)
+	}
+	return nil
+}
+
+// Resume loads a conversation so it can be continued.
+func (s *Store) Resume(id string) ([]*genai.Content, error) {
+	return s.Load(id)
+}
+
+// Branch copies conversation id's log up to (but not including) msgIdx into
+// a new conversation, returning the new conversation's ID. This lets a user
+// edit a prior prompt and re-run without destroying the original thread.
+func (s *Store) Branch(id string, msgIdx int) (string, error) {
+	contents, err := s.Load(id)
+	if err != nil {
+		return "", err
+	}
+	if msgIdx < 0 || msgIdx > len(contents) {
+		return "", fmt.Errorf("message index %d out of range for conversation %s (%d messages)", msgIdx, id, len(contents))
+	}
+
+	newID := newID()
+	if err := s.save(newID, contents[:msgIdx]); err != nil {
+		return "", err
+	}
+	return newID, nil
+}
+
+// newID generates a sortable, collision-resistant conversation ID.
+func newID() string {
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%s-%s", time.Now().Format("20060102-150405"), hex.EncodeToString(buf))
+}
@@ -0,0 +1,228 @@
+package history
+
+import (
+	"context"
+	"iter"
+	"reflect"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestValidateID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		wantErr bool
+	}{
+		{name: "empty", id: "", wantErr: true},
+		{name: "parent traversal", id: "../secret", wantErr: true},
+		{name: "embedded traversal", id: "a/../../b", wantErr: true},
+		{name: "forward slash", id: "a/b", wantErr: true},
+		{name: "backslash", id: `a\b`, wantErr: true},
+		{name: "ordinary id", id: "20260101-120000-abcd1234", wantErr: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateID(tc.id)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("validateID(%q) error = %v, wantErr %v", tc.id, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func textContent(role, text string) *genai.Content {
+	return &genai.Content{Role: role, Parts: []*genai.Part{{Text: text}}}
+}
+
+func TestStoreAppendAndLoad(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	id, err := store.New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	want := []*genai.Content{textContent("user", "hello"), textContent("model", "hi there")}
+	for _, c := range want {
+		if err := store.Append(id, c); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	got, err := store.Load(id)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %#v, want %#v", got, want)
+	}
+
+	if err := store.Remove(id); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := store.Load(id); err == nil {
+		t.Error("Load() after Remove() = nil error, want error")
+	}
+}
+
+func TestStoreBranch(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	id, err := store.New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	contents := []*genai.Content{textContent("user", "a"), textContent("model", "b"), textContent("user", "c")}
+	for _, c := range contents {
+		if err := store.Append(id, c); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	branchID, err := store.Branch(id, 2)
+	if err != nil {
+		t.Fatalf("Branch() error = %v", err)
+	}
+	if branchID == id {
+		t.Fatalf("Branch() returned the original id")
+	}
+
+	got, err := store.Load(branchID)
+	if err != nil {
+		t.Fatalf("Load(branch) error = %v", err)
+	}
+	if !reflect.DeepEqual(got, contents[:2]) {
+		t.Errorf("Load(branch) = %#v, want %#v", got, contents[:2])
+	}
+}
+
+// fakeModel is a minimal model.LLM that always replies with one fixed
+// message, for exercising RecordingModel without a real backend.
+type fakeModel struct {
+	reply string
+}
+
+func (m *fakeModel) Name() string { return "fake" }
+
+func (m *fakeModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{Content: textContent("model", m.reply)}, nil)
+	}
+}
+
+// drain runs it to completion, discarding results, mirroring how llmagent
+// consumes a model.LLM's GenerateContent.
+func drain(it iter.Seq2[*model.LLMResponse, error]) {
+	for range it {
+	}
+}
+
+func TestRecordingModelPersistsTurns(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	rm := NewRecordingModel(&fakeModel{reply: "pong"}, store)
+
+	req := &model.LLMRequest{Contents: []*genai.Content{textContent("user", "ping")}}
+	drain(rm.GenerateContent(context.Background(), req, false))
+
+	ids, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(ids) != 1 {
+		t.Fatalf("List() = %v, want exactly one conversation", ids)
+	}
+
+	got, err := store.Load(ids[0])
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []*genai.Content{textContent("user", "ping"), textContent("model", "pong")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %#v, want %#v", got, want)
+	}
+}
+
+func TestRecordingModelSeparatesConcurrentSessions(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	rm := NewRecordingModel(&fakeModel{reply: "pong"}, store)
+
+	reqA := &model.LLMRequest{Contents: []*genai.Content{textContent("user", "from session A")}}
+	reqB := &model.LLMRequest{Contents: []*genai.Content{textContent("user", "from session B")}}
+	drain(rm.GenerateContent(context.Background(), reqA, false))
+	drain(rm.GenerateContent(context.Background(), reqB, false))
+
+	ids, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("List() = %v, want two separate conversations", ids)
+	}
+
+	for _, id := range ids {
+		contents, err := store.Load(id)
+		if err != nil {
+			t.Fatalf("Load(%s) error = %v", id, err)
+		}
+		if len(contents) != 2 {
+			t.Errorf("Load(%s) = %d messages, want 2 (no cross-session mixing)", id, len(contents))
+		}
+	}
+}
+
+func TestRecordingModelResume(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	priorID, err := store.New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	seed := []*genai.Content{textContent("user", "earlier question"), textContent("model", "earlier answer")}
+	for _, c := range seed {
+		if err := store.Append(priorID, c); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	rm := NewRecordingModel(&fakeModel{reply: "follow-up answer"}, store)
+	rm.Resume(priorID, seed)
+
+	req := &model.LLMRequest{Contents: []*genai.Content{textContent("user", "follow-up question")}}
+	drain(rm.GenerateContent(context.Background(), req, false))
+
+	got, err := store.Load(priorID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := append(append([]*genai.Content{}, seed...), textContent("user", "follow-up question"), textContent("model", "follow-up answer"))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %#v, want %#v", got, want)
+	}
+
+	ids, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(ids) != 1 {
+		t.Errorf("List() = %v, want only the resumed conversation (no duplicate created)", ids)
+	}
+}
@@ -0,0 +1,141 @@
+package history
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"iter"
+	"log"
+	"sync"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// RecordingModel wraps a model.LLM so every turn of every concurrent
+// conversation gets appended to a Store. google.golang.org/adk's model.LLM
+// interface carries no explicit session identifier, so a single
+// RecordingModel shared across concurrent web requests can't just persist
+// everything into one fixed conversation file without mixing unrelated
+// users' turns together. Instead, each distinct conversation is identified
+// by a fingerprint of its first Content (see fingerprint): every call
+// belonging to the same conversation resends the same growing Contents
+// prefix, so the first entry is a stable, self-contained key we can compute
+// without any framework support. This is an approximation (two genuinely
+// different sessions that happen to open with byte-identical content would
+// collide), but it's a strict improvement over a single boot-time
+// conversation id, and it's all that's available without a real session
+// hook from the launcher.
+type RecordingModel struct {
+	model.LLM
+	store *Store
+
+	mu       sync.Mutex
+	sessions map[string]*recordingSession
+
+	// pendingResumeID/pendingResumeSeed, if set, are consumed by the next
+	// brand-new conversation this model sees and splice seed in front of
+	// it under id instead of starting fresh. Set via Resume, which is only
+	// safe to call for single-session (console) runs: in a concurrently
+	// served web session, "the next new conversation" could belong to any
+	// visitor, so resuming there would leak one user's history into
+	// another's.
+	pendingResumeID   string
+	pendingResumeSeed []*genai.Content
+}
+
+// recordingSession tracks how much of one conversation has already been
+// written to the store.
+type recordingSession struct {
+	id       string
+	recorded int              // len(Contents) already appended to store
+	seed     []*genai.Content // resumed history spliced in front of every request
+}
+
+// NewRecordingModel wraps inner so every conversation it carries is
+// persisted to store.
+func NewRecordingModel(inner model.LLM, store *Store) *RecordingModel {
+	return &RecordingModel{LLM: inner, store: store, sessions: make(map[string]*recordingSession)}
+}
+
+// Resume arranges for the next new conversation this model sees to be
+// treated as a continuation of id, with seed spliced in front of it. Only
+// call this for single-session runs (see the RecordingModel doc comment).
+func (rm *RecordingModel) Resume(id string, seed []*genai.Content) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.pendingResumeID = id
+	rm.pendingResumeSeed = seed
+}
+
+// fingerprint derives a stable key for a Content so repeated calls that
+// resend the same conversation prefix land on the same recordingSession.
+func fingerprint(c *genai.Content) string {
+	raw, _ := json.Marshal(c)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateContent records any new turns in req.Contents before delegating to
+// the wrapped model, then records the model's final (non-partial) response.
+func (rm *RecordingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	if len(req.Contents) == 0 {
+		return rm.LLM.GenerateContent(ctx, req, stream)
+	}
+
+	key := fingerprint(req.Contents[0])
+
+	rm.mu.Lock()
+	sess, ok := rm.sessions[key]
+	if !ok {
+		sess = &recordingSession{}
+		if rm.pendingResumeID != "" {
+			sess.id = rm.pendingResumeID
+			sess.seed = rm.pendingResumeSeed
+			sess.recorded = len(sess.seed)
+			rm.pendingResumeID = ""
+			rm.pendingResumeSeed = nil
+			log.Printf("[history] resuming conversation %s (%d prior messages)", sess.id, len(sess.seed))
+		} else if id, err := rm.store.New(); err != nil {
+			log.Printf("[history] failed to start a conversation, continuing without persistence: %v", err)
+		} else {
+			sess.id = id
+			log.Printf("[history] started conversation %s (resume later with --resume %s)", id, id)
+		}
+		rm.sessions[key] = sess
+	}
+	if len(sess.seed) > 0 {
+		req.Contents = append(append([]*genai.Content{}, sess.seed...), req.Contents...)
+	}
+
+	newContents := append([]*genai.Content(nil), req.Contents[sess.recorded:]...)
+	sess.recorded = len(req.Contents)
+	id := sess.id
+	rm.mu.Unlock()
+
+	if id != "" {
+		for _, c := range newContents {
+			if err := rm.store.Append(id, c); err != nil {
+				log.Printf("[history] failed to append turn to conversation %s: %v", id, err)
+			}
+		}
+	}
+
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for resp, err := range rm.LLM.GenerateContent(ctx, req, stream) {
+			if id != "" && err == nil && resp != nil && !resp.Partial && resp.Content != nil {
+				if appendErr := rm.store.Append(id, resp.Content); appendErr != nil {
+					log.Printf("[history] failed to append model turn to conversation %s: %v", id, appendErr)
+				} else {
+					rm.mu.Lock()
+					sess.recorded++
+					rm.mu.Unlock()
+				}
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
@@ -5,28 +5,40 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"iter"
 	"log"
+	"sort"
 
 	"github.com/sashabaranov/go-openai"
 	"google.golang.org/adk/model"
 	"google.golang.org/genai"
+
+	"example.com/adk-agent/pkg/openaiadapter/schema"
 )
 
 // ModelStruct implements the model.LLM interface via sashabaranov/go-openai.
 type ModelStruct struct {
 	client *openai.Client
 	model  string
+
+	// temperature and maxTokens, when set, are copied onto every chat
+	// completion request. nil leaves the backend's own default in place.
+	temperature *float32
+	maxTokens   *int
 }
 
-// NewModel creates a new OpenAI-compatible model instance.
-func NewModel(baseURL, modelName, apiKey string) *ModelStruct {
+// NewModel creates a new OpenAI-compatible model instance. temperature and
+// maxTokens are optional (nil leaves the backend's default behavior).
+func NewModel(baseURL, modelName, apiKey string, temperature *float32, maxTokens *int) *ModelStruct {
 	config := openai.DefaultConfig(apiKey)
 	config.BaseURL = baseURL
 	client := openai.NewClientWithConfig(config)
 	return &ModelStruct{
-		client: client,
-		model:  modelName,
+		client:      client,
+		model:       modelName,
+		temperature: temperature,
+		maxTokens:   maxTokens,
 	}
 }
 
@@ -53,33 +65,190 @@ func (m *ModelStruct) GenerateContent(ctx context.Context, req *model.LLMRequest
 		}
 
 		var tools []openai.Tool
+		var toolSchemas map[string]*schema.Schema
 		if len(req.Tools) > 0 {
-			tools = toOpenAITools(req.Tools)
+			tools, toolSchemas = toOpenAITools(req.Tools)
 		}
 
-		log.Printf("[Ollama] Sending request to model: %s with %d messages and %d tools", m.model, len(messages), len(tools))
+		log.Printf("[Ollama] Sending request to model: %s with %d messages and %d tools (stream=%v)", m.model, len(messages), len(tools), stream)
 
-		resp, err := m.client.CreateChatCompletion(
-			ctx,
-			openai.ChatCompletionRequest{
-				Model:    m.model,
-				Messages: messages,
-				Tools:    tools,
-			},
-		)
-		if err != nil {
-			yield(nil, fmt.Errorf("ollama call failed: %w", err))
+		chatReq := openai.ChatCompletionRequest{
+			Model:    m.model,
+			Messages: messages,
+			Tools:    tools,
+		}
+		if m.temperature != nil {
+			chatReq.Temperature = *m.temperature
+		}
+		if m.maxTokens != nil {
+			chatReq.MaxTokens = *m.maxTokens
+		}
+		attachGrammar(&chatReq, toolSchemas)
+
+		if !stream {
+			resp, err := m.client.CreateChatCompletion(ctx, chatReq)
+			if err != nil {
+				yield(nil, fmt.Errorf("ollama call failed: %w", err))
+				return
+			}
+
+			adkResp, err := toADKResponse(resp, toolSchemas)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to convert response: %w", err))
+				return
+			}
+
+			yield(adkResp, nil)
+			return
+		}
+
+		m.streamChatCompletion(ctx, chatReq, toolSchemas, yield)
+	}
+}
+
+// attachGrammar compiles the declared tool schemas into a single GBNF
+// grammar and attaches it via the ExtraBody hook, so llama.cpp/Ollama
+// backends constrain generation to valid tool-call JSON instead of relying
+// on the model to get argument shapes right on its own. The `grammar` field
+// takes one grammar string, not one per tool, so every tool's shape is
+// folded into a single root alternation (see schema.ToGBNF).
+func attachGrammar(req *openai.ChatCompletionRequest, toolSchemas map[string]*schema.Schema) {
+	grammar := schema.ToGBNF(toolSchemas)
+	if grammar == "" {
+		return
+	}
+
+	if req.ExtraBody == nil {
+		req.ExtraBody = map[string]any{}
+	}
+	req.ExtraBody["grammar"] = grammar
+}
+
+// streamChatCompletion drives the SSE chat-completion stream, yielding one
+// partial *model.LLMResponse per text delta and a final, non-partial response once
+// the stream closes. Tool call argument fragments are accumulated per index
+// until the stream ends, since OpenAI-compatible backends split
+// Function.Arguments across multiple chunks.
+func (m *ModelStruct) streamChatCompletion(ctx context.Context, req openai.ChatCompletionRequest, toolSchemas map[string]*schema.Schema, yield func(*model.LLMResponse, error) bool) {
+	stream, err := m.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		yield(nil, fmt.Errorf("ollama stream call failed: %w", err))
+		return
+	}
+	defer stream.Close()
+
+	var textContent string
+	calls := newToolCallAccumulator()
+
+	for {
+		select {
+		case <-ctx.Done():
+			yield(nil, ctx.Err())
 			return
+		default:
 		}
 
-		adkResp, err := toADKResponse(resp)
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			final := &model.LLMResponse{
+				Content: &genai.Content{Role: "model", Parts: calls.finalize(textContent, toolSchemas)},
+			}
+			yield(final, nil)
+			return
+		}
 		if err != nil {
-			yield(nil, fmt.Errorf("failed to convert response: %w", err))
+			yield(nil, fmt.Errorf("ollama stream recv failed: %w", err))
 			return
 		}
 
-		yield(adkResp, nil)
+		if len(resp.Choices) == 0 {
+			continue
+		}
+		delta := resp.Choices[0].Delta
+
+		if delta.Content != "" {
+			textContent += delta.Content
+			partial := &model.LLMResponse{
+				Content: &genai.Content{Role: "model", Parts: []*genai.Part{{Text: delta.Content}}},
+				Partial: true,
+			}
+			if !yield(partial, nil) {
+				return
+			}
+		}
+
+		for _, tc := range delta.ToolCalls {
+			calls.add(tc)
+		}
+	}
+}
+
+// toolCallAccumulator merges streamed ToolCall deltas by index, concatenating
+// Function.Arguments fragments per call until the stream closes.
+type toolCallAccumulator struct {
+	byIndex map[int]*openai.ToolCall
+	order   []int
+}
+
+func newToolCallAccumulator() *toolCallAccumulator {
+	return &toolCallAccumulator{byIndex: make(map[int]*openai.ToolCall)}
+}
+
+func (a *toolCallAccumulator) add(delta openai.ToolCall) {
+	index := 0
+	if delta.Index != nil {
+		index = *delta.Index
+	}
+
+	tc, ok := a.byIndex[index]
+	if !ok {
+		tc = &openai.ToolCall{ID: delta.ID, Type: delta.Type}
+		a.byIndex[index] = tc
+		a.order = append(a.order, index)
+	}
+	if delta.ID != "" {
+		tc.ID = delta.ID
+	}
+	if delta.Function.Name != "" {
+		tc.Function.Name = delta.Function.Name
+	}
+	tc.Function.Arguments += delta.Function.Arguments
+}
+
+// finalize returns the completed text and function-call parts, with tool
+// calls in ascending stream index order. Arguments are coerced against the
+// tool's declared schema (see toADKResponse) before being returned.
+func (a *toolCallAccumulator) finalize(textContent string, toolSchemas map[string]*schema.Schema) []*genai.Part {
+	var parts []*genai.Part
+	if textContent != "" {
+		parts = append(parts, &genai.Part{Text: textContent})
+	}
+
+	sort.Ints(a.order)
+	for _, index := range a.order {
+		tc := a.byIndex[index]
+		var args map[string]any
+		if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+			log.Printf("[Ollama] Failed to unmarshal streamed tool arguments for %q: %v", tc.Function.Name, err)
+			continue
+		}
+
+		if s := toolSchemas[tc.Function.Name]; s != nil {
+			var mismatches []string
+			args, mismatches = schema.CoerceArgs(s, args)
+			if len(mismatches) > 0 {
+				log.Printf("[Ollama] Tool %q arguments still mismatched after coercion: %v", tc.Function.Name, mismatches)
+			}
+		}
+
+		parts = append(parts, &genai.Part{
+			FunctionCall: &genai.FunctionCall{
+				Name: tc.Function.Name,
+				Args: args,
+			},
+		})
 	}
+	return parts
 }
 
 func toOpenAIMessages(contents []*genai.Content) ([]openai.ChatCompletionMessage, error) {
@@ -186,8 +355,12 @@ type Declarer interface {
 	Declaration() *genai.FunctionDeclaration
 }
 
-func toOpenAITools(adkTools map[string]any) []openai.Tool {
+// toOpenAITools converts ADK tool declarations into OpenAI tool definitions,
+// along with each tool's parsed JSON schema (keyed by tool name) so callers
+// can grammar-constrain generation and coerce mis-typed arguments afterwards.
+func toOpenAITools(adkTools map[string]any) ([]openai.Tool, map[string]*schema.Schema) {
 	var tools []openai.Tool
+	schemas := make(map[string]*schema.Schema)
 	for _, v := range adkTools {
 		var name, description string
 		var parameters any
@@ -226,12 +399,18 @@ func toOpenAITools(adkTools map[string]any) []openai.Tool {
 					Parameters:  parameters,
 				},
 			})
+
+			if s, err := schema.Parse(parameters); err != nil {
+				log.Printf("[Ollama] Failed to parse schema for tool %q: %v", name, err)
+			} else if s != nil {
+				schemas[name] = s
+			}
 		}
 	}
-	return tools
+	return tools, schemas
 }
 
-func toADKResponse(resp openai.ChatCompletionResponse) (*model.LLMResponse, error) {
+func toADKResponse(resp openai.ChatCompletionResponse, toolSchemas map[string]*schema.Schema) (*model.LLMResponse, error) {
 	if len(resp.Choices) == 0 {
 		return nil, errors.New("no choices returned from ollama")
 	}
@@ -250,12 +429,15 @@ func toADKResponse(resp openai.ChatCompletionResponse) (*model.LLMResponse, erro
 			continue
 		}
 
-		// Workaround: Qwen/Ollama sometimes sends "artifact_names": "file.txt"
-		// instead of ["file.txt"]. The ADK tool expects an array.
-		if val, ok := args["artifact_names"]; ok {
-			if strVal, ok := val.(string); ok {
-				log.Printf("[Ollama] Fixing malformed artifact_names: %s -> [%s]", strVal, strVal)
-				args["artifact_names"] = []string{strVal}
+		// Small local models (Qwen via Ollama, etc) frequently send
+		// mis-shaped arguments, e.g. "artifact_names": "file.txt" instead of
+		// ["file.txt"]. Coerce against the declared schema instead of
+		// special-casing each tool.
+		if s := toolSchemas[tc.Function.Name]; s != nil {
+			var mismatches []string
+			args, mismatches = schema.CoerceArgs(s, args)
+			if len(mismatches) > 0 {
+				log.Printf("[Ollama] Tool %q arguments still mismatched after coercion: %v", tc.Function.Name, mismatches)
 			}
 		}
 
@@ -0,0 +1,296 @@
+// Package schema provides lightweight JSON Schema validation, coercion, and
+// GBNF grammar generation for tool arguments returned by OpenAI-compatible
+// backends. Small local models (Qwen via Ollama, llama.cpp, ...) frequently
+// return arguments that don't quite match the declared schema, e.g. a bare
+// string where an array was declared. Rather than special-casing each shape
+// of mistake at the call site, callers parse the declared schema once and
+// run returned arguments through CoerceArgs.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is a minimal JSON Schema representation covering the subset used by
+// ADK tool declarations: objects, arrays, and scalars.
+type Schema struct {
+	Type       string             `json:"type,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Enum       []any              `json:"enum,omitempty"`
+}
+
+// Parse converts a tool's declared parameters (typically a *genai.Schema or
+// an equivalent map[string]any) into a *Schema by round-tripping through
+// JSON, mirroring the json-tag based conversion toOpenAITools already uses
+// for tool declarations that don't implement the Declarer interface. Type
+// names are lowercased, since *genai.Schema marshals them as "OBJECT",
+// "ARRAY", ... where JSON Schema uses "object", "array", ....
+func Parse(parameters any) (*Schema, error) {
+	if parameters == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	var s Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+	normalizeTypes(&s)
+	return &s, nil
+}
+
+// normalizeTypes lowercases s.Type and the types of every nested schema.
+func normalizeTypes(s *Schema) {
+	if s == nil {
+		return
+	}
+	s.Type = strings.ToLower(s.Type)
+	normalizeTypes(s.Items)
+	for _, p := range s.Properties {
+		normalizeTypes(p)
+	}
+}
+
+// CoerceArgs validates args against schema and coerces any mismatched
+// property to the declared type (string->array, string->number, etc). It
+// returns the (possibly mutated) args and the names of properties that
+// still don't match after coercion was attempted.
+func CoerceArgs(s *Schema, args map[string]any) (map[string]any, []string) {
+	if s == nil || len(s.Properties) == 0 {
+		return args, nil
+	}
+
+	var mismatches []string
+	for name, prop := range s.Properties {
+		val, ok := args[name]
+		if !ok || prop == nil || prop.Type == "" {
+			continue
+		}
+		if matchesType(prop.Type, val) {
+			continue
+		}
+		if coerced, ok := coerceValue(prop.Type, val); ok {
+			args[name] = coerced
+		} else {
+			mismatches = append(mismatches, name)
+		}
+	}
+	return args, mismatches
+}
+
+func matchesType(t string, val any) bool {
+	switch t {
+	case "array":
+		_, ok := val.([]any)
+		return ok
+	case "number", "integer":
+		_, ok := val.(float64)
+		return ok
+	case "boolean":
+		_, ok := val.(bool)
+		return ok
+	case "string":
+		_, ok := val.(string)
+		return ok
+	default:
+		return true
+	}
+}
+
+// coerceValue converts val to the declared type t, reporting whether the
+// conversion was possible.
+func coerceValue(t string, val any) (any, bool) {
+	switch t {
+	case "array":
+		if s, ok := val.(string); ok {
+			return []any{s}, true
+		}
+	case "number":
+		if s, ok := val.(string); ok {
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f, true
+			}
+		}
+	case "integer":
+		if s, ok := val.(string); ok {
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f, true
+			}
+		}
+	case "boolean":
+		if s, ok := val.(string); ok {
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b, true
+			}
+		}
+	case "string":
+		switch v := val.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case bool:
+			return strconv.FormatBool(v), true
+		}
+	}
+	return nil, false
+}
+
+// gbnfPrimitives defines the scalar rules every generated grammar depends
+// on. Without these, "number"/"boolean"/"string" in a rule body are
+// undefined references and llama.cpp/Ollama reject the grammar at parse
+// time.
+const gbnfPrimitives = `string ::= "\"" ([^"\\] | "\\" .)* "\""
+number ::= "-"? [0-9]+ ("." [0-9]+)?
+boolean ::= "true" | "false"
+`
+
+// ToGBNF compiles toolSchemas into a single self-contained GBNF grammar
+// string suitable for the `grammar` extra-body field accepted by
+// llama.cpp/Ollama: one `grammar` field takes exactly one grammar, so every
+// declared tool's shape is folded into one `root` alternation (rather than
+// one grammar per tool) with each alternative given its own named rule to
+// avoid collisions between tools' properties.
+func ToGBNF(toolSchemas map[string]*Schema) string {
+	if len(toolSchemas) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(toolSchemas))
+	for name, s := range toolSchemas {
+		if s != nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+
+	var alternatives []string
+	var rules strings.Builder
+	for _, name := range names {
+		ruleName := gbnfRuleName(name)
+		alternatives = append(alternatives, ruleName)
+		fmt.Fprintf(&rules, "%s ::= ", ruleName)
+		writeGBNFRule(&rules, toolSchemas[name])
+		rules.WriteString("\n")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "root ::= %s\n", strings.Join(alternatives, " | "))
+	b.WriteString(rules.String())
+	b.WriteString(gbnfPrimitives)
+	return b.String()
+}
+
+// gbnfRuleName derives a GBNF-safe rule name from a tool name, since GBNF
+// rule names only allow [A-Za-z0-9_-].
+func gbnfRuleName(toolName string) string {
+	var b strings.Builder
+	b.WriteString("tool_")
+	for _, r := range toolName {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func writeGBNFRule(b *strings.Builder, s *Schema) {
+	switch s.Type {
+	case "object":
+		writeGBNFObjectRule(b, s)
+	case "array":
+		b.WriteString("\"[\" ")
+		if s.Items != nil {
+			writeGBNFRule(b, s.Items)
+		}
+		b.WriteString(" \"]\"")
+	case "number", "integer":
+		b.WriteString("number")
+	case "boolean":
+		b.WriteString("boolean")
+	default:
+		b.WriteString("string")
+	}
+}
+
+// writeGBNFObjectRule emits an object's fields with s.Required properties
+// forced (so the model must fill them in) and every other property grouped
+// into a single truncatable-from-the-end optional tail (so the model may
+// leave them out instead of being forced to fabricate a value). This is an
+// approximation of "any subset of optional properties in any order" - the
+// grammar only allows dropping a suffix of the optional properties (sorted
+// by name), not an arbitrary middle one, which keeps the comma bookkeeping
+// unambiguous while still letting the common "all optional" and "none
+// optional" cases work correctly.
+func writeGBNFObjectRule(b *strings.Builder, s *Schema) {
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	var reqNames, optNames []string
+	for name := range s.Properties {
+		if required[name] {
+			reqNames = append(reqNames, name)
+		} else {
+			optNames = append(optNames, name)
+		}
+	}
+	sort.Strings(reqNames)
+	sort.Strings(optNames)
+
+	b.WriteString("\"{\" ")
+	for i, name := range reqNames {
+		if i > 0 {
+			b.WriteString(" \",\" ")
+		}
+		fmt.Fprintf(b, "%q \":\" ", name)
+		writeGBNFRule(b, s.Properties[name])
+	}
+	if len(optNames) > 0 {
+		if len(reqNames) > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(writeGBNFOptionalTail(optNames, s.Properties, len(reqNames) > 0))
+	}
+	b.WriteString(" \"}\"")
+}
+
+// writeGBNFOptionalTail builds a nested-optional grammar fragment for
+// names: the property names[0] may be present only together with a
+// (recursively optional) tail for names[1:], so a property can only be
+// omitted if every optional property after it is also omitted.
+// needsLeadingComma is true whenever something (a required property, or an
+// earlier optional property in this same tail) may already have been
+// written before names[0].
+func writeGBNFOptionalTail(names []string, props map[string]*Schema, needsLeadingComma bool) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	var field strings.Builder
+	if needsLeadingComma {
+		field.WriteString("\",\" ")
+	}
+	fmt.Fprintf(&field, "%q \":\" ", names[0])
+	writeGBNFRule(&field, props[names[0]])
+
+	if rest := writeGBNFOptionalTail(names[1:], props, true); rest != "" {
+		field.WriteString(" ")
+		field.WriteString(rest)
+	}
+	return "(" + field.String() + ")?"
+}
@@ -0,0 +1,249 @@
+package schema
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCoerceArgs(t *testing.T) {
+	s := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"artifact_names": {Type: "array", Items: &Schema{Type: "string"}},
+			"count":          {Type: "integer"},
+			"temperature":    {Type: "number"},
+			"verbose":        {Type: "boolean"},
+			"label":          {Type: "string"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		wantArgs map[string]any
+		wantMiss []string
+	}{
+		{
+			name:     "qwen single string instead of array",
+			args:     map[string]any{"artifact_names": "file.txt"},
+			wantArgs: map[string]any{"artifact_names": []any{"file.txt"}},
+		},
+		{
+			name:     "already an array is left alone",
+			args:     map[string]any{"artifact_names": []any{"a.txt", "b.txt"}},
+			wantArgs: map[string]any{"artifact_names": []any{"a.txt", "b.txt"}},
+		},
+		{
+			name:     "string to integer",
+			args:     map[string]any{"count": "3"},
+			wantArgs: map[string]any{"count": float64(3)},
+		},
+		{
+			name:     "string to number",
+			args:     map[string]any{"temperature": "0.7"},
+			wantArgs: map[string]any{"temperature": 0.7},
+		},
+		{
+			name:     "string to boolean",
+			args:     map[string]any{"verbose": "true"},
+			wantArgs: map[string]any{"verbose": true},
+		},
+		{
+			name:     "number to string",
+			args:     map[string]any{"label": float64(42)},
+			wantArgs: map[string]any{"label": "42"},
+		},
+		{
+			name:     "unconvertible value reported as a mismatch",
+			args:     map[string]any{"count": "not-a-number"},
+			wantArgs: map[string]any{"count": "not-a-number"},
+			wantMiss: []string{"count"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, mismatches := CoerceArgs(s, tc.args)
+			if !reflect.DeepEqual(got, tc.wantArgs) {
+				t.Errorf("CoerceArgs() args = %#v, want %#v", got, tc.wantArgs)
+			}
+			if !reflect.DeepEqual(mismatches, tc.wantMiss) && !(len(mismatches) == 0 && len(tc.wantMiss) == 0) {
+				t.Errorf("CoerceArgs() mismatches = %v, want %v", mismatches, tc.wantMiss)
+			}
+		})
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	raw := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query": map[string]any{"type": "string"},
+		},
+		"required": []any{"query"},
+	}
+
+	s, err := Parse(raw)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if s.Type != "object" {
+		t.Errorf("Type = %q, want %q", s.Type, "object")
+	}
+	if s.Properties["query"] == nil || s.Properties["query"].Type != "string" {
+		t.Errorf("Properties[query] = %#v, want type string", s.Properties["query"])
+	}
+	if !reflect.DeepEqual(s.Required, []string{"query"}) {
+		t.Errorf("Required = %v, want [query]", s.Required)
+	}
+}
+
+// definedGBNFRules returns the set of rule names a GBNF grammar defines
+// (the left-hand side of every "name ::=" line).
+func definedGBNFRules(grammar string) map[string]bool {
+	defined := map[string]bool{}
+	re := regexp.MustCompile(`(?m)^([A-Za-z_][A-Za-z0-9_-]*)\s*::=`)
+	for _, m := range re.FindAllStringSubmatch(grammar, -1) {
+		defined[m[1]] = true
+	}
+	return defined
+}
+
+// referencedGBNFRules returns every bare identifier used in a GBNF grammar
+// once quoted literals and character classes are stripped out, i.e. the
+// rule names the grammar actually references.
+func referencedGBNFRules(grammar string) []string {
+	noStrings := regexp.MustCompile(`"(\\.|[^"\\])*"`).ReplaceAllString(grammar, "")
+	noCharClasses := regexp.MustCompile(`\[[^\]]*\]`).ReplaceAllString(noStrings, "")
+	return regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_-]*`).FindAllString(noCharClasses, -1)
+}
+
+// assertGBNFSelfContained fails the test if grammar references any rule
+// name it doesn't also define. This is the check that would have caught the
+// "number"/"boolean"/"string" undefined-rule bug in writeGBNFRule.
+func assertGBNFSelfContained(t *testing.T, grammar string) {
+	t.Helper()
+	defined := definedGBNFRules(grammar)
+	for _, ref := range referencedGBNFRules(grammar) {
+		if ref == "root" {
+			continue
+		}
+		if !defined[ref] {
+			t.Errorf("grammar references undefined rule %q:\n%s", ref, grammar)
+		}
+	}
+}
+
+func TestToGBNFEmpty(t *testing.T) {
+	if got := ToGBNF(nil); got != "" {
+		t.Errorf("ToGBNF(nil) = %q, want empty", got)
+	}
+	if got := ToGBNF(map[string]*Schema{"search": nil}); got != "" {
+		t.Errorf("ToGBNF(all-nil) = %q, want empty", got)
+	}
+}
+
+func TestToGBNFSingleTool(t *testing.T) {
+	toolSchemas := map[string]*Schema{
+		"search": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"query": {Type: "string"},
+				"count": {Type: "integer"},
+			},
+		},
+	}
+
+	grammar := ToGBNF(toolSchemas)
+	if !strings.Contains(grammar, "root ::= tool_search\n") {
+		t.Errorf("grammar root alternation = %q, want to list tool_search", grammar)
+	}
+	if !strings.Contains(grammar, "tool_search ::=") {
+		t.Errorf("grammar missing tool_search rule:\n%s", grammar)
+	}
+	assertGBNFSelfContained(t, grammar)
+}
+
+func TestToGBNFMultipleTools(t *testing.T) {
+	toolSchemas := map[string]*Schema{
+		"search": {
+			Type:       "object",
+			Properties: map[string]*Schema{"query": {Type: "string"}},
+		},
+		"fetch": {
+			Type:       "object",
+			Properties: map[string]*Schema{"url": {Type: "string"}, "verbose": {Type: "boolean"}},
+		},
+		"calc": {
+			Type:       "object",
+			Properties: map[string]*Schema{"values": {Type: "array", Items: &Schema{Type: "number"}}},
+		},
+	}
+
+	grammar := ToGBNF(toolSchemas)
+
+	rootLine := ""
+	for _, line := range strings.Split(grammar, "\n") {
+		if strings.HasPrefix(line, "root ::=") {
+			rootLine = line
+			break
+		}
+	}
+	for _, name := range []string{"tool_search", "tool_fetch", "tool_calc"} {
+		if !strings.Contains(rootLine, name) {
+			t.Errorf("root alternation %q missing %q", rootLine, name)
+		}
+		if !strings.Contains(grammar, name+" ::=") {
+			t.Errorf("grammar missing rule definition for %q:\n%s", name, grammar)
+		}
+	}
+
+	// Every primitive type used above (string, boolean, number) must be
+	// defined, not just referenced.
+	assertGBNFSelfContained(t, grammar)
+}
+
+func TestToGBNFOptionalPropertiesAreNotForced(t *testing.T) {
+	toolSchemas := map[string]*Schema{
+		"search": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"query": {Type: "string"},
+				"limit": {Type: "integer"},
+			},
+			Required: []string{"query"},
+		},
+	}
+
+	grammar := ToGBNF(toolSchemas)
+	assertGBNFSelfContained(t, grammar)
+
+	ruleBody := gbnfRuleBody(t, grammar, "tool_search")
+	if !strings.Contains(ruleBody, `"query"`) {
+		t.Errorf("tool_search rule %q missing required property %q", ruleBody, "query")
+	}
+	if !strings.Contains(ruleBody, `("limit"`) && !strings.Contains(ruleBody, `"limit"`) {
+		t.Errorf("tool_search rule %q missing optional property %q", ruleBody, "limit")
+	}
+	if !strings.Contains(ruleBody, ")?") {
+		t.Errorf("tool_search rule %q doesn't mark %q optional: want a \")?\" group", ruleBody, "limit")
+	}
+}
+
+// gbnfRuleBody returns the text after "<ruleName> ::= " up to the newline
+// that starts the grammar's next rule definition.
+func gbnfRuleBody(t *testing.T, grammar, ruleName string) string {
+	t.Helper()
+	marker := ruleName + " ::= "
+	idx := strings.Index(grammar, marker)
+	if idx < 0 {
+		t.Fatalf("grammar missing rule %q:\n%s", ruleName, grammar)
+	}
+	rest := grammar[idx+len(marker):]
+	if nl := strings.Index(rest, "\n"); nl >= 0 {
+		return rest[:nl]
+	}
+	return rest
+}
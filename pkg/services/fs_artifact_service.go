@@ -2,34 +2,78 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"google.golang.org/adk/artifact"
 	"google.golang.org/genai"
 )
 
-// FileSystemArtifactService implements artifact.Service using the local file system.
+// Meta describes one stored version of an artifact.
+type Meta struct {
+	MimeType  string    `json:"mime_type"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Backend persists artifact versions. FileSystemArtifactService delegates
+// all storage to a Backend so a non-local implementation (e.g. S3) can be
+// dropped in without touching the artifact.Service conversion logic.
+type Backend interface {
+	// List returns the names of artifacts with at least one stored version.
+	List(ctx context.Context) ([]string, error)
+	// Versions returns the stored version numbers for fileName, ascending.
+	Versions(ctx context.Context, fileName string) ([]int64, error)
+	// Write stores data as a new version of fileName and returns its number.
+	Write(ctx context.Context, fileName string, data []byte, mimeType string) (int64, error)
+	// Read returns the data and metadata for fileName at version. version<=0
+	// means the latest version.
+	Read(ctx context.Context, fileName string, version int64) ([]byte, Meta, error)
+	// DeleteVersion removes a single stored version.
+	DeleteVersion(ctx context.Context, fileName string, version int64) error
+	// DeleteArtifact removes every stored version of fileName.
+	DeleteArtifact(ctx context.Context, fileName string) error
+}
+
+// FileSystemArtifactService implements artifact.Service using the local file
+// system. Versioned artifacts (written via Save) live under RootDir as
+// RootDir/<filename>/vN.bin plus a RootDir/<filename>/vN.meta.json sidecar;
+// pre-existing plain files directly under RootDir remain readable (but not
+// writable) for backwards compatibility with the original read-only service.
 type FileSystemArtifactService struct {
 	RootDir string
+	Backend Backend
 }
 
-// NewFileSystemArtifactService creates a new service rooted at the given directory.
+// NewFileSystemArtifactService creates a new service rooted at the given
+// directory, backed by the local file system.
 func NewFileSystemArtifactService(rootDir string) *FileSystemArtifactService {
-	return &FileSystemArtifactService{RootDir: rootDir}
+	return &FileSystemArtifactService{
+		RootDir: rootDir,
+		Backend: NewLocalBackend(rootDir),
+	}
 }
 
-// List returns a list of files in the root directory.
+// List returns the names of plain files and versioned artifacts under RootDir.
 func (s *FileSystemArtifactService) List(ctx context.Context, req *artifact.ListRequest) (*artifact.ListResponse, error) {
-	var fileNames []string
-
 	entries, err := os.ReadDir(s.RootDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
+	var fileNames []string
 	for _, entry := range entries {
 		// Ignore hidden files and directories for simplicity
 		if strings.HasPrefix(entry.Name(), ".") {
@@ -37,45 +81,90 @@ func (s *FileSystemArtifactService) List(ctx context.Context, req *artifact.List
 		}
 		if !entry.IsDir() {
 			fileNames = append(fileNames, entry.Name())
+			continue
+		}
+		if versions, err := s.Backend.Versions(ctx, entry.Name()); err == nil && len(versions) > 0 {
+			fileNames = append(fileNames, entry.Name())
 		}
 	}
 
 	return &artifact.ListResponse{FileNames: fileNames}, nil
 }
 
-// Load reads a file from disk and returns it as an artifact.
+// Load reads an artifact and returns it as a Part. Versioned artifacts honor
+// req.Version (0/unset means latest); pre-existing plain files are read
+// directly. Text content is returned as a FunctionResponse "content" string
+// for backwards compatibility; everything else is returned as InlineData
+// with its detected mime type.
 func (s *FileSystemArtifactService) Load(ctx context.Context, req *artifact.LoadRequest) (*artifact.LoadResponse, error) {
-	// Security check: simple path traversal prevention
-	if strings.Contains(req.FileName, "..") || strings.HasPrefix(req.FileName, "/") {
-		return nil, fmt.Errorf("invalid filename: %s", req.FileName)
+	if err := validateFileName(req.FileName); err != nil {
+		return nil, err
 	}
 
-	fullPath := filepath.Join(s.RootDir, req.FileName)
-	content, err := os.ReadFile(fullPath)
+	content, mimeType, err := s.readArtifact(ctx, req.FileName, req.Version)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %w", req.FileName, err)
+		return nil, err
 	}
 
 	fmt.Printf("[FSArtifactService] Successfully read '%s'. Size: %d bytes\n", req.FileName, len(content))
 
-	// Optimization: If text/plain, return as FunctionResponse part
-	// This ensures it passes through ADK/GenAI layers as a PROPER tool response for OpenAI Adapter
+	if strings.HasPrefix(mimeType, "text/") {
+		return &artifact.LoadResponse{
+			Part: &genai.Part{
+				FunctionResponse: &genai.FunctionResponse{
+					Name: "load_artifacts", // Match the tool name for ID generation
+					Response: map[string]any{
+						"content": string(content),
+					},
+				},
+			},
+		}, nil
+	}
+
 	return &artifact.LoadResponse{
 		Part: &genai.Part{
-			FunctionResponse: &genai.FunctionResponse{
-				Name: "load_artifacts", // Match the tool name for ID generation
-				Response: map[string]any{
-					"content": string(content),
-				},
+			InlineData: &genai.Blob{
+				MIMEType: mimeType,
+				Data:     content,
 			},
 		},
 	}, nil
 }
 
-// Versions returns available versions for an artifact.
-// For FileSystem, we only support specific version "1" if file exists.
+// readArtifact resolves fileName to either a versioned artifact (if a
+// matching directory exists under RootDir) or a plain legacy file.
+func (s *FileSystemArtifactService) readArtifact(ctx context.Context, fileName string, version int64) ([]byte, string, error) {
+	if versions, err := s.Backend.Versions(ctx, fileName); err == nil && len(versions) > 0 {
+		data, meta, err := s.Backend.Read(ctx, fileName, version)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read artifact %s: %w", fileName, err)
+		}
+		return data, meta.MimeType, nil
+	}
+
+	fullPath := filepath.Join(s.RootDir, fileName)
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file %s: %w", fileName, err)
+	}
+	return content, detectMimeType(fileName, content), nil
+}
+
+// Versions returns the stored version numbers for an artifact.
 func (s *FileSystemArtifactService) Versions(ctx context.Context, req *artifact.VersionsRequest) (*artifact.VersionsResponse, error) {
-	// Check if file exists
+	if err := validateFileName(req.FileName); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.Backend.Versions(ctx, req.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions for %s: %w", req.FileName, err)
+	}
+	if len(versions) > 0 {
+		return &artifact.VersionsResponse{Versions: versions}, nil
+	}
+
+	// Fall back to the legacy single-version behavior for plain files.
 	fullPath := filepath.Join(s.RootDir, req.FileName)
 	if _, err := os.Stat(fullPath); err == nil {
 		return &artifact.VersionsResponse{Versions: []int64{1}}, nil
@@ -83,17 +172,254 @@ func (s *FileSystemArtifactService) Versions(ctx context.Context, req *artifact.
 	return &artifact.VersionsResponse{Versions: []int64{}}, nil
 }
 
-// Save is not supported (Read-Only).
+// Save writes a new version of an artifact and returns the assigned version.
 func (s *FileSystemArtifactService) Save(ctx context.Context, req *artifact.SaveRequest) (*artifact.SaveResponse, error) {
-	return nil, fmt.Errorf("save not supported by FileSystemArtifactService")
+	if err := validateFileName(req.FileName); err != nil {
+		return nil, err
+	}
+	if req.Part == nil {
+		return nil, fmt.Errorf("save requires a part")
+	}
+
+	data, mimeType := partBytes(req.Part)
+	version, err := s.Backend.Write(ctx, req.FileName, data, mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save artifact %s: %w", req.FileName, err)
+	}
+
+	return &artifact.SaveResponse{Version: version}, nil
 }
 
-// Delete is not supported (Read-Only).
+// Delete removes a single stored version of an artifact.
 func (s *FileSystemArtifactService) Delete(ctx context.Context, req *artifact.DeleteRequest) error {
-	return fmt.Errorf("delete not supported by FileSystemArtifactService")
+	if err := validateFileName(req.FileName); err != nil {
+		return err
+	}
+	if err := s.Backend.DeleteVersion(ctx, req.FileName, req.Version); err != nil {
+		return fmt.Errorf("failed to delete %s version %d: %w", req.FileName, req.Version, err)
+	}
+	return nil
 }
 
-// DeleteAll is not supported (Read-Only).
+// DeleteAll removes every stored version of an artifact.
 func (s *FileSystemArtifactService) DeleteAll(ctx context.Context, req *artifact.DeleteRequest) error {
-	return fmt.Errorf("delete_all not supported by FileSystemArtifactService")
+	if err := validateFileName(req.FileName); err != nil {
+		return err
+	}
+	if err := s.Backend.DeleteArtifact(ctx, req.FileName); err != nil {
+		return fmt.Errorf("failed to delete all versions of %s: %w", req.FileName, err)
+	}
+	return nil
+}
+
+// validateFileName guards against path traversal out of RootDir.
+func validateFileName(fileName string) error {
+	if strings.Contains(fileName, "..") || strings.HasPrefix(fileName, "/") {
+		return fmt.Errorf("invalid filename: %s", fileName)
+	}
+	return nil
+}
+
+// partBytes extracts the raw bytes and a best-guess mime type from a Part,
+// so Save can handle both inline binary data and plain text parts.
+func partBytes(p *genai.Part) ([]byte, string) {
+	if p.InlineData != nil {
+		mimeType := p.InlineData.MIMEType
+		if mimeType == "" {
+			mimeType = http.DetectContentType(p.InlineData.Data)
+		}
+		return p.InlineData.Data, mimeType
+	}
+	return []byte(p.Text), "text/plain; charset=utf-8"
+}
+
+// detectMimeType guesses a mime type from the file extension, falling back
+// to content sniffing.
+func detectMimeType(fileName string, content []byte) string {
+	if ext := filepath.Ext(fileName); ext != "" {
+		if mimeType := mime.TypeByExtension(ext); mimeType != "" {
+			return mimeType
+		}
+	}
+	return http.DetectContentType(content)
+}
+
+// LocalBackend is the on-disk Backend used by default: versions are stored
+// as RootDir/<filename>/vN.bin with a RootDir/<filename>/vN.meta.json
+// sidecar.
+type LocalBackend struct {
+	RootDir string
+
+	mu        sync.Mutex // guards fileLocks
+	fileLocks map[string]*sync.Mutex
+}
+
+// NewLocalBackend creates a Backend rooted at rootDir.
+func NewLocalBackend(rootDir string) *LocalBackend {
+	return &LocalBackend{RootDir: rootDir, fileLocks: make(map[string]*sync.Mutex)}
+}
+
+// lockFile serializes Write calls for the same fileName, so two concurrent
+// Saves can't both read the same "next version" and clobber each other.
+// Returns the unlock function to call (typically via defer).
+func (b *LocalBackend) lockFile(fileName string) func() {
+	b.mu.Lock()
+	lock, ok := b.fileLocks[fileName]
+	if !ok {
+		lock = &sync.Mutex{}
+		b.fileLocks[fileName] = lock
+	}
+	b.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+func (b *LocalBackend) artifactDir(fileName string) string {
+	return filepath.Join(b.RootDir, fileName)
+}
+
+func (b *LocalBackend) versionPath(fileName string, version int64) string {
+	return filepath.Join(b.artifactDir(fileName), fmt.Sprintf("v%d.bin", version))
+}
+
+func (b *LocalBackend) metaPath(fileName string, version int64) string {
+	return filepath.Join(b.artifactDir(fileName), fmt.Sprintf("v%d.meta.json", version))
+}
+
+func (b *LocalBackend) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(b.RootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if versions, err := b.Versions(ctx, entry.Name()); err == nil && len(versions) > 0 {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (b *LocalBackend) Versions(ctx context.Context, fileName string) ([]int64, error) {
+	entries, err := os.ReadDir(b.artifactDir(fileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var versions []int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".bin") || !strings.HasPrefix(name, "v") {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, "v"), ".bin"), 10, 64)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, n)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions, nil
+}
+
+func (b *LocalBackend) Write(ctx context.Context, fileName string, data []byte, mimeType string) (int64, error) {
+	defer b.lockFile(fileName)()
+
+	dir := b.artifactDir(fileName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	versions, err := b.Versions(ctx, fileName)
+	if err != nil {
+		return 0, err
+	}
+	version := int64(1)
+	if len(versions) > 0 {
+		version = versions[len(versions)-1] + 1
+	}
+
+	sum := sha256.Sum256(data)
+	meta := Meta{
+		MimeType:  mimeType,
+		Size:      int64(len(data)),
+		SHA256:    hex.EncodeToString(sum[:]),
+		Timestamp: time.Now(),
+	}
+
+	if err := writeFileSynced(b.versionPath(fileName, version), data); err != nil {
+		return 0, err
+	}
+
+	metaJSON, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err := writeFileSynced(b.metaPath(fileName, version), metaJSON); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func (b *LocalBackend) Read(ctx context.Context, fileName string, version int64) ([]byte, Meta, error) {
+	if version <= 0 {
+		versions, err := b.Versions(ctx, fileName)
+		if err != nil {
+			return nil, Meta{}, err
+		}
+		if len(versions) == 0 {
+			return nil, Meta{}, fmt.Errorf("no versions found for %s", fileName)
+		}
+		version = versions[len(versions)-1]
+	}
+
+	data, err := os.ReadFile(b.versionPath(fileName, version))
+	if err != nil {
+		return nil, Meta{}, err
+	}
+
+	var meta Meta
+	if metaJSON, err := os.ReadFile(b.metaPath(fileName, version)); err == nil {
+		_ = json.Unmarshal(metaJSON, &meta)
+	}
+	return data, meta, nil
+}
+
+func (b *LocalBackend) DeleteVersion(ctx context.Context, fileName string, version int64) error {
+	if err := os.Remove(b.versionPath(fileName, version)); err != nil {
+		return err
+	}
+	_ = os.Remove(b.metaPath(fileName, version))
+	return nil
+}
+
+func (b *LocalBackend) DeleteArtifact(ctx context.Context, fileName string) error {
+	return os.RemoveAll(b.artifactDir(fileName))
+}
+
+// writeFileSynced writes data to path and fsyncs it before closing, so a
+// Save is durable even if the process is killed immediately after.
+func writeFileSynced(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to fsync %s: %w", path, err)
+	}
+	return nil
 }
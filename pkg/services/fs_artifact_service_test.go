@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestLocalBackendWriteAssignsSequentialVersions(t *testing.T) {
+	backend := NewLocalBackend(t.TempDir())
+	ctx := context.Background()
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := backend.Write(ctx, "doc.txt", []byte("content"), "text/plain")
+		if err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Write() version = %d, want %d", got, want)
+		}
+	}
+}
+
+// TestLocalBackendWriteConcurrentSaves exercises the race the per-filename
+// lock in lockFile closes: many goroutines saving the same artifact at once
+// must each get a distinct version instead of two computing the same "next
+// version" and one clobbering the other.
+func TestLocalBackendWriteConcurrentSaves(t *testing.T) {
+	backend := NewLocalBackend(t.TempDir())
+	ctx := context.Background()
+
+	const writers = 20
+	versions := make([]int64, writers)
+	errs := make([]error, writers)
+
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for i := 0; i < writers; i++ {
+		go func(i int) {
+			defer wg.Done()
+			versions[i], errs[i] = backend.Write(ctx, "shared.txt", []byte("payload"), "text/plain")
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, writers)
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Write() goroutine %d error = %v", i, err)
+		}
+		if seen[versions[i]] {
+			t.Fatalf("version %d assigned more than once across concurrent writes: %v", versions[i], versions)
+		}
+		seen[versions[i]] = true
+	}
+
+	got, err := backend.Versions(ctx, "shared.txt")
+	if err != nil {
+		t.Fatalf("Versions() error = %v", err)
+	}
+	if len(got) != writers {
+		t.Errorf("Versions() = %v, want %d distinct versions", got, writers)
+	}
+}
@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// fetchMaxBytes caps how much of a page body we'll read, so a huge response
+// can't blow up the context window.
+const fetchMaxBytes = 200_000
+
+// FetchArgs defines the arguments for the fetch tool.
+type FetchArgs struct {
+	URL string `json:"url" description:"The URL to fetch and read."`
+}
+
+// FetchOutput defines the output of the fetch tool.
+type FetchOutput struct {
+	Content string `json:"content"`
+}
+
+// NewFetchTool creates a tool that GETs a URL and returns its cleaned text,
+// so the agent can search-then-read instead of only citing search snippets.
+func NewFetchTool() (tool.Tool, error) {
+	// A plain newHTTPClient() would resolve the hostname once here to
+	// validate it and then let http.Client resolve it again (independently)
+	// to connect, which a DNS-rebinding attacker can exploit: return a public
+	// IP for the validation lookup, then a disallowed one (e.g. 127.0.0.1,
+	// the cloud metadata address) by the time the connection is actually
+	// dialed. safeDialContext closes that gap by resolving and validating
+	// the host itself and dialing the literal validated IP, and it does so
+	// on every connection this client makes, including redirect hops.
+	client := &http.Client{
+		Timeout:   httpClientTimeout,
+		Transport: &http.Transport{DialContext: safeDialContext},
+	}
+
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "fetch",
+			Description: "Fetch a URL and return its text content. Use this to read a page found via 'search'.",
+		},
+		func(ctx tool.Context, args FetchArgs) (FetchOutput, error) {
+			target, err := validateFetchURL(args.URL)
+			if err != nil {
+				return FetchOutput{}, err
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+			if err != nil {
+				return FetchOutput{}, fmt.Errorf("invalid URL %q: %w", args.URL, err)
+			}
+			req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; adk-agent/1.0)")
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return FetchOutput{}, fmt.Errorf("failed to fetch %q: %w", args.URL, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return FetchOutput{}, fmt.Errorf("fetch %q returned status %d", args.URL, resp.StatusCode)
+			}
+
+			raw, err := io.ReadAll(io.LimitReader(resp.Body, fetchMaxBytes))
+			if err != nil {
+				return FetchOutput{}, fmt.Errorf("failed to read %q: %w", args.URL, err)
+			}
+
+			return FetchOutput{Content: cleanHTML(string(raw))}, nil
+		},
+	)
+}
+
+// validateFetchURL restricts the fetch tool to http/https URLs with a host.
+// raw comes straight from model output (a search snippet it read, or a URL a
+// prior search result gave it), which an attacker can influence via indirect
+// prompt injection. The actual defense against that URL reaching an internal
+// service (the cloud metadata endpoint, localhost, ...) lives in
+// safeDialContext, which validates whatever IP is resolved at the moment a
+// connection is actually dialed; a hostname-based check here would only
+// protect the first connection attempt, not any redirect hops.
+func validateFetchURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q in %q: only http and https are allowed", u.Scheme, raw)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("URL %q has no host", raw)
+	}
+	return u, nil
+}
+
+// isDisallowedFetchIP reports whether ip is a loopback, private, link-local,
+// or unspecified address that the fetch tool must never connect to.
+func isDisallowedFetchIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// safeDialContext is the fetch client's Transport.DialContext: it resolves
+// addr's host itself, rejects it if any resolved IP is disallowed, and then
+// dials the literal validated IP rather than the hostname. Because
+// http.Transport calls DialContext again for every connection it opens
+// (including one per redirect hop), this re-validates on each hop instead of
+// trusting a one-time, pre-request hostname lookup that an attacker could
+// defeat by having the name resolve differently a moment later
+// (DNS rebinding).
+func safeDialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve host %q: %w", host, err)
+	}
+	for _, ip := range ips {
+		if isDisallowedFetchIP(ip.IP) {
+			return nil, fmt.Errorf("refusing to connect to %q: host %q resolves to a disallowed address %s", addr, host, ip.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("host %q did not resolve to any address", host)
+	}
+
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
+}
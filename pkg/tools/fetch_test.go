@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestValidateFetchURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{name: "ordinary https URL", raw: "https://example.com/page", wantErr: false},
+		{name: "ordinary http URL", raw: "http://example.com/page", wantErr: false},
+		{name: "unsupported scheme", raw: "file:///etc/passwd", wantErr: true},
+		{name: "no host", raw: "https:///path", wantErr: true},
+		{name: "unparseable", raw: "://bad", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := validateFetchURL(tc.raw)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("validateFetchURL(%q) error = %v, wantErr %v", tc.raw, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsDisallowedFetchIP(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   string
+		want bool
+	}{
+		{name: "loopback v4", ip: "127.0.0.1", want: true},
+		{name: "loopback v6", ip: "::1", want: true},
+		{name: "private 10/8", ip: "10.0.0.5", want: true},
+		{name: "private 192.168/16", ip: "192.168.1.1", want: true},
+		{name: "link-local unicast", ip: "169.254.169.254", want: true},
+		{name: "unspecified", ip: "0.0.0.0", want: true},
+		{name: "public address", ip: "93.184.216.34", want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ip := net.ParseIP(tc.ip)
+			if ip == nil {
+				t.Fatalf("net.ParseIP(%q) = nil", tc.ip)
+			}
+			if got := isDisallowedFetchIP(ip); got != tc.want {
+				t.Errorf("isDisallowedFetchIP(%q) = %v, want %v", tc.ip, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestSafeDialContextRejectsDisallowedIPs exercises the dial-time guard
+// directly, which is what stands between a DNS-rebound hostname and an
+// internal address: even though validateFetchURL no longer rejects these
+// hosts up front, safeDialContext must still refuse to connect to them.
+func TestSafeDialContextRejectsDisallowedIPs(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+	}{
+		{name: "loopback", addr: "127.0.0.1:80"},
+		{name: "cloud metadata address", addr: "169.254.169.254:80"},
+		{name: "private network", addr: "10.0.0.1:80"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, err := safeDialContext(context.Background(), "tcp", tc.addr)
+			if err == nil {
+				conn.Close()
+				t.Fatalf("safeDialContext(%q) succeeded, want a refusal error", tc.addr)
+			}
+			if !strings.Contains(err.Error(), "disallowed address") {
+				t.Errorf("safeDialContext(%q) error = %v, want a disallowed-address refusal", tc.addr, err)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package tools
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
@@ -12,34 +15,67 @@ type SearchArgs struct {
 	Query string `json:"query" description:"The search query to find information about."`
 }
 
-// SearchResult defines the output of the search tool.
+// SearchResult is one hit returned by a SearchProvider. Title/URL/Snippet
+// let the LLM cite its sources instead of just asserting facts.
 type SearchResult struct {
-	Results []string `json:"results"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
 }
 
-// NewSearchTool creates a new simple search tool.
-func NewSearchTool() (tool.Tool, error) {
+// SearchOutput defines the output of the search tool.
+type SearchOutput struct {
+	Results []SearchResult `json:"results"`
+}
+
+// defaultResultCount is how many results every search tool call asks its
+// provider for; SearchArgs has no field to change it.
+const defaultResultCount = 5
+
+// SearchProvider performs a web search and returns up to k results.
+type SearchProvider interface {
+	Search(ctx context.Context, query string, k int) ([]SearchResult, error)
+}
+
+// ProviderFromEnv picks a SearchProvider based on ADK_SEARCH_PROVIDER
+// (google|searxng|ddg|mock), defaulting to mock when unset.
+func ProviderFromEnv() (SearchProvider, error) {
+	switch strings.ToLower(os.Getenv("ADK_SEARCH_PROVIDER")) {
+	case "google":
+		return NewGoogleCSEProvider()
+	case "searxng":
+		return NewSearxNGProvider(os.Getenv("ADK_SEARXNG_URL"))
+	case "ddg", "duckduckgo":
+		return NewDuckDuckGoProvider(), nil
+	case "", "mock":
+		return NewMockProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown ADK_SEARCH_PROVIDER: %s", os.Getenv("ADK_SEARCH_PROVIDER"))
+	}
+}
+
+// NewSearchTool creates the "search" tool backed by provider. A nil provider
+// is resolved via ProviderFromEnv.
+func NewSearchTool(provider SearchProvider) (tool.Tool, error) {
+	if provider == nil {
+		var err error
+		provider, err = ProviderFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to select search provider: %w", err)
+		}
+	}
+
 	return functiontool.New(
 		functiontool.Config{
 			Name:        "search",
 			Description: "Search the web for information. Use this tool finding real-time data or facts.",
 		},
-		func(ctx tool.Context, args SearchArgs) (SearchResult, error) {
-			// In a real implementation, this would call Google Search API or similar.
-			// For this local Ollama demo, we simulate a response.
-
-			query := args.Query
-			results := []string{
-				fmt.Sprintf("Result 1 for '%s': This is a simulated search result.", query),
-				fmt.Sprintf("Result 2: details about %s found on the web.", query),
+		func(ctx tool.Context, args SearchArgs) (SearchOutput, error) {
+			results, err := provider.Search(ctx, args.Query, defaultResultCount)
+			if err != nil {
+				return SearchOutput{}, fmt.Errorf("search failed: %w", err)
 			}
-
-			// Mock specific data if needed for the "Capital of France" query
-			if query == "Capital of France" || query == "capital of France" {
-				results = []string{"The capital of France is Paris."}
-			}
-
-			return SearchResult{Results: results}, nil
+			return SearchOutput{Results: results}, nil
 		},
 	)
 }
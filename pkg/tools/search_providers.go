@@ -0,0 +1,243 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// httpClientTimeout bounds every provider's outbound request so a slow or
+// hanging search backend can't stall the agent turn indefinitely.
+const httpClientTimeout = 10 * time.Second
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: httpClientTimeout}
+}
+
+// MockProvider preserves the original simulated search behavior, used when
+// ADK_SEARCH_PROVIDER is unset and in tests.
+type MockProvider struct{}
+
+// NewMockProvider creates a MockProvider.
+func NewMockProvider() *MockProvider {
+	return &MockProvider{}
+}
+
+func (p *MockProvider) Search(ctx context.Context, query string, k int) ([]SearchResult, error) {
+	if strings.EqualFold(query, "Capital of France") {
+		return []SearchResult{{
+			Title:   "Capital of France",
+			URL:     "https://en.wikipedia.org/wiki/Paris",
+			Snippet: "The capital of France is Paris.",
+		}}, nil
+	}
+
+	return []SearchResult{
+		{Title: fmt.Sprintf("Result 1 for %q", query), URL: "https://example.com/1", Snippet: "This is a simulated search result."},
+		{Title: "Result 2", URL: "https://example.com/2", Snippet: fmt.Sprintf("Details about %s found on the web.", query)},
+	}, nil
+}
+
+// GoogleCSEProvider searches via the Google Custom Search JSON API.
+type GoogleCSEProvider struct {
+	CSEID  string
+	APIKey string
+	client *http.Client
+}
+
+// NewGoogleCSEProvider builds a GoogleCSEProvider from GOOGLE_CSE_ID and
+// GOOGLE_API_KEY.
+func NewGoogleCSEProvider() (*GoogleCSEProvider, error) {
+	cseID := strings.TrimSpace(os.Getenv("GOOGLE_CSE_ID"))
+	apiKey := strings.TrimSpace(os.Getenv("GOOGLE_API_KEY"))
+	if cseID == "" || apiKey == "" {
+		return nil, fmt.Errorf("GOOGLE_CSE_ID and GOOGLE_API_KEY must be set to use the google search provider")
+	}
+	return &GoogleCSEProvider{CSEID: cseID, APIKey: apiKey, client: newHTTPClient()}, nil
+}
+
+func (p *GoogleCSEProvider) Search(ctx context.Context, query string, k int) ([]SearchResult, error) {
+	endpoint := fmt.Sprintf(
+		"https://www.googleapis.com/customsearch/v1?key=%s&cx=%s&q=%s&num=%d",
+		url.QueryEscape(p.APIKey), url.QueryEscape(p.CSEID), url.QueryEscape(query), k,
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("google CSE request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("google CSE returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Items []struct {
+			Title   string `json:"title"`
+			Link    string `json:"link"`
+			Snippet string `json:"snippet"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode google CSE response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(body.Items))
+	for _, item := range body.Items {
+		results = append(results, SearchResult{Title: item.Title, URL: item.Link, Snippet: item.Snippet})
+	}
+	return results, nil
+}
+
+// SearxNGProvider searches a self-hosted SearxNG instance over its JSON API.
+type SearxNGProvider struct {
+	BaseURL string
+	client  *http.Client
+}
+
+// NewSearxNGProvider creates a SearxNGProvider against baseURL (e.g.
+// "http://localhost:8080").
+func NewSearxNGProvider(baseURL string) (*SearxNGProvider, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("searxng base URL must be set (ADK_SEARXNG_URL)")
+	}
+	return &SearxNGProvider{BaseURL: strings.TrimSuffix(baseURL, "/"), client: newHTTPClient()}, nil
+}
+
+func (p *SearxNGProvider) Search(ctx context.Context, query string, k int) ([]SearchResult, error) {
+	endpoint := fmt.Sprintf("%s/search?format=json&q=%s", p.BaseURL, url.QueryEscape(query))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("searxng request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("searxng returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode searxng response: %w", err)
+	}
+
+	count := len(body.Results)
+	if k < count {
+		count = k
+	}
+	results := make([]SearchResult, 0, count)
+	for i, item := range body.Results {
+		if i >= k {
+			break
+		}
+		results = append(results, SearchResult{Title: item.Title, URL: item.URL, Snippet: item.Content})
+	}
+	return results, nil
+}
+
+// DuckDuckGoProvider scrapes DuckDuckGo's keyless HTML results page, for use
+// when no API key is configured.
+type DuckDuckGoProvider struct {
+	client *http.Client
+}
+
+// NewDuckDuckGoProvider creates a DuckDuckGoProvider.
+func NewDuckDuckGoProvider() *DuckDuckGoProvider {
+	return &DuckDuckGoProvider{client: newHTTPClient()}
+}
+
+var ddgResultPattern = regexp.MustCompile(`(?s)<a[^>]*class="result__a"[^>]*href="([^"]+)"[^>]*>(.*?)</a>.*?<a[^>]*class="result__snippet"[^>]*>(.*?)</a>`)
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+func (p *DuckDuckGoProvider) Search(ctx context.Context, query string, k int) ([]SearchResult, error) {
+	endpoint := "https://html.duckduckgo.com/html/?q=" + url.QueryEscape(query)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; adk-agent/1.0)")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("duckduckgo request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("duckduckgo returned status %d", resp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read duckduckgo response: %w", err)
+	}
+
+	var results []SearchResult
+	for _, m := range ddgResultPattern.FindAllStringSubmatch(string(raw), -1) {
+		if len(results) >= k {
+			break
+		}
+		results = append(results, SearchResult{
+			URL:     resolveDuckDuckGoURL(m[1]),
+			Title:   cleanHTML(m[2]),
+			Snippet: cleanHTML(m[3]),
+		})
+	}
+	return results, nil
+}
+
+// resolveDuckDuckGoURL extracts the real target URL from one of DuckDuckGo's
+// HTML result links. Those links point at DuckDuckGo's own redirect handler
+// (protocol-relative "//duckduckgo.com/l/?uddg=<encoded-url>&rut=..."), not
+// the article itself, so callers that fetch href verbatim get a schemeless
+// URL pointing at duckduckgo.com instead of the page they asked for.
+func resolveDuckDuckGoURL(href string) string {
+	if strings.HasPrefix(href, "//") {
+		href = "https:" + href
+	}
+
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+
+	// u.Query().Get already percent-decodes the uddg value for us.
+	if uddg := u.Query().Get("uddg"); uddg != "" {
+		return uddg
+	}
+	return u.String()
+}
+
+// cleanHTML strips tags and collapses whitespace, shared by the DuckDuckGo
+// scraper and the fetch tool.
+func cleanHTML(s string) string {
+	s = htmlTagPattern.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(s), " ")
+}
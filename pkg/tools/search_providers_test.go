@@ -0,0 +1,40 @@
+package tools
+
+import "testing"
+
+func TestResolveDuckDuckGoURL(t *testing.T) {
+	tests := []struct {
+		name string
+		href string
+		want string
+	}{
+		{
+			name: "protocol-relative redirect link",
+			href: "//duckduckgo.com/l/?uddg=https%3A%2F%2Fexample.com%2Farticle&rut=abc123",
+			want: "https://example.com/article",
+		},
+		{
+			name: "absolute redirect link",
+			href: "https://duckduckgo.com/l/?uddg=https%3A%2F%2Fexample.com%2Fpage",
+			want: "https://example.com/page",
+		},
+		{
+			name: "already a direct link",
+			href: "https://example.com/direct",
+			want: "https://example.com/direct",
+		},
+		{
+			name: "unparseable href returned verbatim",
+			href: "://bad",
+			want: "://bad",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveDuckDuckGoURL(tc.href); got != tc.want {
+				t.Errorf("resolveDuckDuckGoURL(%q) = %q, want %q", tc.href, got, tc.want)
+			}
+		})
+	}
+}